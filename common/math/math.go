@@ -0,0 +1,47 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package math provides integer math utilities.
+package math
+
+const (
+	MaxUint64 = 1<<64 - 1
+)
+
+// SafeAdd returns a + b and checks for overflow. ok is false when the
+// addition would have wrapped around the uint64 boundary, in which case
+// the returned sum is meaningless.
+func SafeAdd(a, b uint64) (uint64, bool) {
+	c := a + b
+	return c, c >= a
+}
+
+// SafeSub returns a - b and checks for underflow. ok is false when b is
+// greater than a, in which case the returned difference is meaningless.
+func SafeSub(a, b uint64) (uint64, bool) {
+	return a - b, a >= b
+}
+
+// SafeMul returns a * b and checks for overflow. ok is false when the
+// multiplication would have wrapped around the uint64 boundary, in which
+// case the returned product is meaningless.
+func SafeMul(a, b uint64) (uint64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	return c, c/b == a
+}
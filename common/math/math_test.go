@@ -0,0 +1,90 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package math
+
+import "testing"
+
+func TestSafeAdd(t *testing.T) {
+	tests := []struct {
+		a, b   uint64
+		want   uint64
+		wantOk bool
+	}{
+		{0, 0, 0, true},
+		{1, 2, 3, true},
+		{MaxUint64, 0, MaxUint64, true},
+		{MaxUint64 - 1, 1, MaxUint64, true},
+		{MaxUint64, 1, 0, false},
+		{MaxUint64, MaxUint64, 0, false},
+	}
+	for _, test := range tests {
+		got, ok := SafeAdd(test.a, test.b)
+		if ok != test.wantOk {
+			t.Errorf("SafeAdd(%d, %d) ok = %v, want %v", test.a, test.b, ok, test.wantOk)
+		}
+		if ok && got != test.want {
+			t.Errorf("SafeAdd(%d, %d) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestSafeSub(t *testing.T) {
+	tests := []struct {
+		a, b   uint64
+		want   uint64
+		wantOk bool
+	}{
+		{0, 0, 0, true},
+		{3, 2, 1, true},
+		{MaxUint64, MaxUint64, 0, true},
+		{0, 1, 0, false},
+		{MaxUint64 - 1, MaxUint64, 0, false},
+	}
+	for _, test := range tests {
+		got, ok := SafeSub(test.a, test.b)
+		if ok != test.wantOk {
+			t.Errorf("SafeSub(%d, %d) ok = %v, want %v", test.a, test.b, ok, test.wantOk)
+		}
+		if ok && got != test.want {
+			t.Errorf("SafeSub(%d, %d) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestSafeMul(t *testing.T) {
+	tests := []struct {
+		a, b   uint64
+		want   uint64
+		wantOk bool
+	}{
+		{0, 0, 0, true},
+		{0, MaxUint64, 0, true},
+		{3, 4, 12, true},
+		{MaxUint64, 1, MaxUint64, true},
+		{1 << 32, 1 << 32, 0, false},
+		{MaxUint64, 2, 0, false},
+	}
+	for _, test := range tests {
+		got, ok := SafeMul(test.a, test.b)
+		if ok != test.wantOk {
+			t.Errorf("SafeMul(%d, %d) ok = %v, want %v", test.a, test.b, ok, test.wantOk)
+		}
+		if ok && got != test.want {
+			t.Errorf("SafeMul(%d, %d) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
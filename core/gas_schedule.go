@@ -0,0 +1,90 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// GasScheduleWindow maintains the rolling window of gas votes cast over
+// the current params.GasVoteWindow-block epoch, along with the
+// vm.GasSchedule currently in effect. Headers are fed to it through Add
+// (or Reset after a reorg) and the active schedule is read back through
+// Active. Nothing in this package calls Add from block insertion or
+// block validation.
+type GasScheduleWindow struct {
+	votes  []vm.GasSchedule // votes cast in the current epoch, oldest first
+	active vm.GasSchedule
+}
+
+// NewGasScheduleWindow returns a window seeded with the frontier gas
+// schedule as its initial active vector.
+func NewGasScheduleWindow() *GasScheduleWindow {
+	return &GasScheduleWindow{active: vm.FrontierGasSchedule}
+}
+
+// Active returns the gas schedule currently in effect.
+func (w *GasScheduleWindow) Active() vm.GasSchedule {
+	return w.active
+}
+
+// Add validates header's proposed vote against the active schedule and
+// the consensus floors, appends it to the current epoch, and — when the
+// header's number is a multiple of params.GasVoteWindow — recomputes the
+// active schedule as the per-field median of the votes collected since
+// the previous recomputation, however many there are. A header whose vote fails
+// validation must not be accepted onto the chain.
+func (w *GasScheduleWindow) Add(header *types.Header) error {
+	vote := vm.GasSchedule(header.GasVote)
+
+	if err := vm.CheckGasVote(vote, w.active); err != nil {
+		return fmt.Errorf("block #%v: %v", header.Number, err)
+	}
+	w.votes = append(w.votes, vote)
+
+	if header.Number.Uint64()%params.GasVoteWindow == 0 {
+		w.active = vm.MedianGasSchedule(w.votes)
+		w.votes = w.votes[:0]
+	}
+	return nil
+}
+
+// Reset rebuilds the window from scratch given the headers of a reorged
+// chain segment (oldest first), so that a reorg crossing an epoch
+// boundary recomputes the active schedule from the new canonical history
+// rather than keeping the one derived from the abandoned fork.
+//
+// preActive is the schedule that was active immediately before the
+// segment's first header, i.e. the active schedule at the reorg's common
+// ancestor. Seeding from it (rather than always from
+// vm.FrontierGasSchedule) keeps every vote's 2x-move check in Add
+// anchored to the correct baseline for reorgs that don't span the chain's
+// entire history.
+func (w *GasScheduleWindow) Reset(preActive vm.GasSchedule, headers []*types.Header) error {
+	w.votes = w.votes[:0]
+	w.active = preActive
+	for _, header := range headers {
+		if err := w.Add(header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
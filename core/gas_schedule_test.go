@@ -0,0 +1,89 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// voteHeader builds a header at number whose vote matches active in every
+// field except Call, which is set to call. Starting from active (rather
+// than casting call across every field) keeps the step fields within 2x of
+// their own active values, since they and Call move on independent scales.
+func voteHeader(number uint64, active vm.GasSchedule, call uint64) *types.Header {
+	vote := params.GasVote(active)
+	vote.Call = call
+	return &types.Header{Number: new(big.Int).SetUint64(number), GasVote: vote}
+}
+
+func TestGasScheduleWindowEpochRollover(t *testing.T) {
+	w := NewGasScheduleWindow()
+	before := w.Active()
+
+	for i := uint64(1); i <= params.GasVoteWindow; i++ {
+		if err := w.Add(voteHeader(i, before, before.Call)); err != nil {
+			t.Fatalf("Add(%d): %v", i, err)
+		}
+	}
+
+	if got := w.Active().Call; got != before.Call {
+		t.Fatalf("active Call after rollover = %d, want unchanged %d", got, before.Call)
+	}
+	if len(w.votes) != 0 {
+		t.Fatalf("votes not cleared after epoch rollover, len = %d", len(w.votes))
+	}
+}
+
+func TestGasScheduleWindowReorg(t *testing.T) {
+	w := NewGasScheduleWindow()
+	base := w.Active()
+
+	// Run two full epochs, each voting the call price up to its 2x
+	// ceiling, so the active schedule ends up at 4x the frontier default —
+	// far enough from it that a reorg seeded from the wrong baseline is
+	// guaranteed to reject the segment below.
+	for epoch := 0; epoch < 2; epoch++ {
+		active := w.Active()
+		for i := uint64(1); i <= params.GasVoteWindow; i++ {
+			num := uint64(epoch)*params.GasVoteWindow + i
+			if err := w.Add(voteHeader(num, active, active.Call*2)); err != nil {
+				t.Fatalf("Add(%d): %v", num, err)
+			}
+		}
+	}
+	preReorgActive := w.Active()
+	if preReorgActive.Call != base.Call*4 {
+		t.Fatalf("active Call after two epochs = %d, want %d", preReorgActive.Call, base.Call*4)
+	}
+
+	// Reorg in a short segment that only votes the active Call price
+	// unchanged. If Reset seeded from vm.FrontierGasSchedule instead of
+	// the pre-segment active value, these votes (4x the frontier default)
+	// would be rejected as moving too far from the wrong baseline.
+	reorged := []*types.Header{voteHeader(2*params.GasVoteWindow+1, preReorgActive, preReorgActive.Call)}
+	if err := w.Reset(preReorgActive, reorged); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if got := w.Active(); got.Call != preReorgActive.Call {
+		t.Fatalf("active Call after reorg = %d, want %d", got.Call, preReorgActive.Call)
+	}
+}
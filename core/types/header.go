@@ -0,0 +1,39 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package types contains data types central to the blockchain.
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Header represents a block header in the Ethereum blockchain.
+type Header struct {
+	Number   *big.Int `json:"number"   gencodec:"required"`
+	GasLimit *big.Int `json:"gasLimit" gencodec:"required"`
+	GasUsed  *big.Int `json:"gasUsed"  gencodec:"required"`
+
+	// GasVote carries this block's proposed dynamic gas schedule. Every
+	// params.GasVoteWindow blocks the chain recomputes the active
+	// schedule as the per-field median of the votes cast over the
+	// preceding window; see core/gas_schedule.go. It is a plain
+	// exported struct field so it's picked up by RLP encoding like the
+	// rest of the header.
+	GasVote params.GasVote `json:"gasVote" gencodec:"required"`
+}
@@ -0,0 +1,55 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Environment is the interface the interpreter and its opcodes use to
+// reach outside of the currently executing contract: chain configuration,
+// the block the contract is running in, and calls into other contracts.
+type Environment interface {
+	// ChainConfig returns the chain's fork configuration, used to select
+	// the active params.GasTable for the current block.
+	ChainConfig() *params.ChainConfig
+
+	// BlockNumber returns the number of the block the current execution
+	// takes place in.
+	BlockNumber() *big.Int
+
+	// GasSchedule returns the dynamic gas schedule currently in effect,
+	// as maintained by the chain's GasScheduleWindow (see
+	// core/gas_schedule.go).
+	GasSchedule() GasSchedule
+
+	// Call executes addr's code in its own context, passing it args and
+	// value, metered by gas. It returns the callee's return data and the
+	// gas left over.
+	Call(caller *Contract, addr common.Address, args []byte, gas uint64, price, value *big.Int) ([]byte, uint64, error)
+
+	// CallCode is like Call, except that it executes addr's code in
+	// caller's own context.
+	CallCode(caller *Contract, addr common.Address, args []byte, gas uint64, price, value *big.Int) ([]byte, uint64, error)
+
+	// DelegateCall is like CallCode, except that it also forwards
+	// caller's original caller and value instead of caller's own.
+	DelegateCall(caller *Contract, addr common.Address, args []byte, gas uint64) ([]byte, uint64, error)
+}
@@ -17,313 +17,133 @@
 package vm
 
 import (
-	"fmt"
+	"errors"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/params"
 )
 
+// ErrGasUintOverflow is returned when the 64 bit gas counter would wrap
+// around while charging for an opcode.
+var ErrGasUintOverflow = errors.New("gas uint64 overflow")
+
 // These are the minimum amount the gas price can be.
 // It is set to the current gas price on ethereum so miners can decide what these become.
-var (
-	MinGasQuickStep   = big.NewInt(2)
-	MinGasFastestStep = big.NewInt(3)
-	MinGasFastStep    = big.NewInt(5)
-	MinGasMidStep     = big.NewInt(8)
-	MinGasSlowStep    = big.NewInt(10)
-	MinGasExtStep     = big.NewInt(20)
-	
-	// TODO: Replace these with current values.
-	MinGasSload = big.NewInt(500)
-	MinGasStore = big.NewInt(500)
-	MinGasSha3 = big.NewInt(30)
-	MinGasCreate = big.NewInt(500)
-	MinGasCall = big.NewInt(500)
-	MinGasJumpdest = big.NewInt(10)
-	MinGasSuicide = big.NewInt(0)
-	MinGasBalance = big.NewInt(20)
-	MinGasExtcodesize = big.NewInt(20)
-	MinGasExtcodecopy = big.NewInt(20)
-
-	GasReturn = big.NewInt(0)
-	GasStop   = big.NewInt(0)
+const (
+	MinGasQuickStep   uint64 = 2
+	MinGasFastestStep uint64 = 3
+	MinGasFastStep    uint64 = 5
+	MinGasMidStep     uint64 = 8
+	MinGasSlowStep    uint64 = 10
+	MinGasExtStep     uint64 = 20
 
-	GasContractByte = big.NewInt(200)
+	// TODO: Replace these with current values.
+	MinGasSload       uint64 = 500
+	MinGasStore       uint64 = 500
+	MinGasSha3        uint64 = 30
+	MinGasCreate      uint64 = 500
+	MinGasCall        uint64 = 500
+	MinGasJumpdest    uint64 = 10
+	MinGasSuicide     uint64 = 0
+	MinGasBalance     uint64 = 20
+	MinGasExtcodesize uint64 = 20
+	MinGasExtcodecopy uint64 = 20
+
+	GasReturn uint64 = 0
+	GasStop   uint64 = 0
+
+	GasContractByte uint64 = 200
 )
 
-// These are the params which are targetable.
-type dynamicGas struct {
-	// Step opcodes (affects multiple opcodes).
-    quickStep *big.Int
-    fastestStep *big.Int
-	fastStep *big.Int
-	midStep *big.Int
-	slowStep *big.Int
-	extStep *big.Int
-	
-	// Seperate opcodes.
-	sload *big.Int
-	sstore *big.Int
-	sha3 *big.Int
-	create *big.Int
-	call *big.Int
-	jumpdest *big.Int
-	suicide *big.Int
-	balance *big.Int
-	extcodesize *big.Int
-	extcodecopy *big.Int
-}
-
-// This should be used to check the opcode targets per block.
-// Then every 64 blocks it should then use those to retarget the gas prices.
-func checkGasPricing(*dynamicGas dynGas) error {
-	
-	// Check to make sure that we are not setting the gas price too low.
-	// Preventing of reaching 0 gas for function (inf loop) and really cheap for attacks.
-	if (dynGas.quickStep.Cmp(MinGasQuickStep) == -1 || 
-		dynGas.fastestStep.Cmp(MinGasFastestStep) == -1 || 
-		dynGas.fastStep.Cmp(MinGasFastStep) == -1 || 
-		dynGas.midStep.Cmp(MinGasMidStep) == -1 || 
-		dynGas.slowStep.Cmp(MinGasSlowStep) == -1 || 
-		dynGas.extStep.Cmp(MinGasExtStep) == -1 || 
-		// speific opcodes.
-		dynGas.sload.Cmp(MinGasSload) == -1 || 
-		dynGas.sstore.Cmp(MinGasStore) == -1 || 
-		dynGas.sha3.Cmp(MinGasSha3) == -1 || 
-		dynGas.create.Cmp(MinGasCreate) == -1 || 
-		dynGas.call.Cmp(MinGasCall) == -1 || 
-		dynGas.jumpdest.Cmp(MinGasJumpdest) == -1 || 
-		dynGas.suicide.Cmp(MinGasSuicide) == -1 || 
-		dynGas.balance.Cmp(MinGasBalance) == -1 || 
-		dynGas.extcodesize.Cmp(MinGasExtcodesize) == -1 || 
-		dynGas.extcodecopy.Cmp(MinGasExtcodecopy) == -1) {
-			return fmt.Errorf("Block tried to set gas price of opcode too low.")
-		}
-		
-	// Also make sure this block is not trying to retarget too high.
-	// This is to prevent targeting 9999999999 to increase to an insane value even if the other 63 vote low.
-	// So the max a block can vote is an 2x increase / decrease.
-	// This does mean that if the target goes above 0 then it can never get back to zero and 1 would be the minimum.
-	// Keep the divisor constant, don't create a new bigint each calc.
-	divisor := big.NewInt(2)
-	
-	if (dynGas.quickStep.Cmp(big.NewInt(0).Mul(_baseCheck[GAS].gas, divisor)) == 1 ||
-		dynGas.quickStep.Cmp(big.NewInt(0).Div(_baseCheck[GAS].gas, divisor)) == -1 ||
-		
-		dynGas.fastestStep.Cmp(big.NewInt(0).Mul(_baseCheck[ADD].gas, divisor)) == 1 ||
-		dynGas.fastestStep.Cmp(big.NewInt(0).Div(_baseCheck[ADD].gas, divisor)) == -1 ||
-		
-		dynGas.fastStep.Cmp(big.NewInt(0).Mul(_baseCheck[MOD].gas, divisor)) == 1 ||
-		dynGas.fastStep.Cmp(big.NewInt(0).Div(_baseCheck[MOD].gas, divisor)) == -1 ||
-		
-		dynGas.midStep.Cmp(big.NewInt(0).Mul(_baseCheck[JUMP].gas, divisor)) == 1 ||
-		dynGas.midStep.Cmp(big.NewInt(0).Div(_baseCheck[JUMP].gas, divisor)) == -1 ||
-		
-		dynGas.slowStep.Cmp(big.NewInt(0).Mul(_baseCheck[EXP].gas, divisor)) == 1 ||
-		dynGas.slowStep.Cmp(big.NewInt(0).Div(_baseCheck[EXP].gas, divisor)) == -1 ||
-		
-		dynGas.extStep.Cmp(big.NewInt(0).Mul(_baseCheck[BLOCKHASH].gas, divisor)) == 1 ||
-		dynGas.extStep.Cmp(big.NewInt(0).Div(_baseCheck[BLOCKHASH].gas, divisor)) == -1 ||
-		
-		dynGas.sload.Cmp(big.NewInt(0).Mul(_baseCheck[SLOAD].gas, divisor)) == 1 ||
-		dynGas.sload.Cmp(big.NewInt(0).Div(_baseCheck[SLOAD].gas, divisor)) == -1 ||
-		
-		dynGas.sstore.Cmp(big.NewInt(0).Mul(_baseCheck[SSTORE].gas, divisor)) == 1 ||
-		dynGas.sstore.Cmp(big.NewInt(0).Div(_baseCheck[SSTORE].gas, divisor)) == -1// ||
-		
-		// Continue...
-		) {
-			return fmt.Errorf("Block tried to set gas price of opcode too high.")
-		}
+// Step opcode gas costs. They seed the step fields of FrontierGasSchedule
+// (see gas_schedule.go) and are passed to stepGas as fallback prices in
+// jump_table.go; every opcode given one of them there is covered by
+// GasSchedule.priceFor, so the voted price is what gets charged.
+// These are distinct from the MinGas* floors above, which only bound how
+// far the dynamic gas schedule is allowed to move.
+const (
+	GasQuickStep   uint64 = 2
+	GasFastestStep uint64 = 3
+	GasFastStep    uint64 = 5
+	GasMidStep     uint64 = 8
+	GasSlowStep    uint64 = 10
+	GasExtStep     uint64 = 20
+)
 
-	return nil
+// opGasPrice returns the gas price for op, falling back to base (the
+// dynamically voted price from GasSchedule.priceFor, or the caller's
+// fallback where the schedule has none) unless op is one of the handful
+// of instructions the active gas table (gt) reprices on a per-fork basis.
+func opGasPrice(op OpCode, base uint64, gt params.GasTable) uint64 {
+	switch op {
+	case BALANCE:
+		return gt.Balance
+	case EXTCODESIZE:
+		return gt.ExtcodeSize
+	case EXTCODECOPY:
+		return gt.ExtcodeCopy
+	case SLOAD:
+		return gt.SLoad
+	case CALL, CALLCODE, DELEGATECALL:
+		return gt.Calls
+	case SUICIDE:
+		return gt.Suicide
+	default:
+		return base
+	}
 }
 
-// Update the gas pricing with the last subset of blocks.
-// The gas is not stored and will need to be recalculated from the last 64 blocks.
-func updateGasPricing(*dynamicGas dynGas) error {
+// gasExp returns EXP's gas cost: its step price (the dynamic gas
+// schedule's vote if it has one, else GasSlowStep) plus gt.ExpByte for
+// every byte of the exponent operand.
+func gasExp(gt params.GasTable, gs GasSchedule, env Environment, contract *Contract, stack *stack, memory *Memory) (uint64, error) {
+	expByteLen := uint64((stack.back(1).BitLen() + 7) / 8)
 
-	// Make sure that the gas target is valid.
-	// Since each block should be checked this should never happen.
-	gasCheck := checkGasPricing(dynGas)
-	
-	if (gasCheck != nil) {
-		return gasCheck
+	byteCost, ok := math.SafeMul(expByteLen, gt.ExpByte)
+	if !ok {
+		return 0, ErrGasUintOverflow
 	}
-
-	_baseCheck = map[OpCode]req{
-		// opcode  |  stack pop | gas price | stack push
-		ADD:          {2, dynamicGas.fastestStep, 1},
-		LT:           {2, dynamicGas.fastestStep, 1},
-		GT:           {2, dynamicGas.fastestStep, 1},
-		SLT:          {2, dynamicGas.fastestStep, 1},
-		SGT:          {2, dynamicGas.fastestStep, 1},
-		EQ:           {2, dynamicGas.fastestStep, 1},
-		ISZERO:       {1, dynamicGas.fastestStep, 1},
-		SUB:          {2, dynamicGas.fastestStep, 1},
-		AND:          {2, dynamicGas.fastestStep, 1},
-		OR:           {2, dynamicGas.fastestStep, 1},
-		XOR:          {2, dynamicGas.fastestStep, 1},
-		NOT:          {1, dynamicGas.fastestStep, 1},
-		BYTE:         {2, dynamicGas.fastestStep, 1},
-		CALLDATALOAD: {1, dynamicGas.fastestStep, 1},
-		CALLDATACOPY: {3, dynamicGas.fastestStep, 1},
-		MLOAD:        {1, dynamicGas.fastestStep, 1},
-		MSTORE:       {2, dynamicGas.fastestStep, 0},
-		MSTORE8:      {2, dynamicGas.fastestStep, 0},
-		CODECOPY:     {3, dynamicGas.fastestStep, 0},
-		MUL:          {2, dynamicGas.fastStep, 1},
-		DIV:          {2, dynamicGas.fastStep, 1},
-		SDIV:         {2, dynamicGas.fastStep, 1},
-		MOD:          {2, dynamicGas.fastStep, 1},
-		SMOD:         {2, dynamicGas.fastStep, 1},
-		SIGNEXTEND:   {2, dynamicGas.fastStep, 1},
-		ADDMOD:       {3, dynamicGas.midStep, 1},
-		MULMOD:       {3, dynamicGas.midStep, 1},
-		JUMP:         {1, dynamicGas.midStep, 0},
-		JUMPI:        {2, dynamicGas.slowStep, 0},
-		EXP:          {2, dynamicGas.slowStep, 1},
-		ADDRESS:      {0, dynamicGas.quickStep, 1},
-		ORIGIN:       {0, dynamicGas.quickStep, 1},
-		CALLER:       {0, dynamicGas.quickStep, 1},
-		CALLVALUE:    {0, dynamicGas.quickStep, 1},
-		CODESIZE:     {0, dynamicGas.quickStep, 1},
-		GASPRICE:     {0, dynamicGas.quickStep, 1},
-		COINBASE:     {0, dynamicGas.quickStep, 1},
-		TIMESTAMP:    {0, dynamicGas.quickStep, 1},
-		NUMBER:       {0, dynamicGas.quickStep, 1},
-		CALLDATASIZE: {0, dynamicGas.quickStep, 1},
-		DIFFICULTY:   {0, dynamicGas.quickStep, 1},
-		GASLIMIT:     {0, dynamicGas.quickStep, 1},
-		POP:          {1, dynamicGas.quickStep, 0},
-		PC:           {0, dynamicGas.quickStep, 1},
-		MSIZE:        {0, dynamicGas.quickStep, 1},
-		GAS:          {0, dynamicGas.quickStep, 1},
-		BLOCKHASH:    {1, dynamicGas.extStep, 1},
-		BALANCE:      {1, dynamicGas.balance, 1},
-		EXTCODESIZE:  {1, dynamicGas.extcodesize, 1},
-		EXTCODECOPY:  {4, dynamicGas.extcodecopy, 0},
-		SLOAD:        {1, dynamicGas.sload, 1},
-		SSTORE:       {2, dynamicGas.sstore, 0},
-		SHA3:         {2, dynamicGas.sha3, 1},
-		CREATE:       {3, dynamicGas.create, 1},
-		CALL:         {7, dynamicGas.call, 1},
-		CALLCODE:     {7, dynamicGas.call, 1},
-		DELEGATECALL: {6, dynamicGas.call, 1},
-		JUMPDEST:     {0, dynamicGas.jumpdest, 0},
-		SUICIDE:      {1, dynamicGas.suicide, 0},
-		RETURN:       {2, Zero, 0},
-		PUSH1:        {0, dynamicGas.fastestStep, 1},
-		DUP1:         {0, Zero, 1},
+	cost, ok := math.SafeAdd(opGasPrice(EXP, gs.priceFor(EXP, GasSlowStep), gt), byteCost)
+	if !ok {
+		return 0, ErrGasUintOverflow
 	}
-	
-	return nil
+	return cost, nil
 }
 
-// baseCheck checks for any stack error underflows
-func baseCheck(op OpCode, stack *stack, gas *big.Int) error {
-	// PUSH and DUP are a bit special. They all cost the same but we do want to have checking on stack push limit
-	// PUSH is also allowed to calculate the same price for all PUSHes
-	// DUP requirements are handled elsewhere (except for the stack limit check)
-	if op >= PUSH1 && op <= PUSH32 {
-		op = PUSH1
-	}
-	if op >= DUP1 && op <= DUP16 {
-		op = DUP1
-	}
-
-	if r, ok := _baseCheck[op]; ok {
-		err := stack.require(r.stackPop)
-		if err != nil {
-			return err
+// callGas returns the actual gas cost for the call family of opcodes
+// (CALL, CALLCODE, DELEGATECALL). gt.CreateBySuicide is non-zero once the
+// EIP150 gas table is active, which is when the "all but one 64th"
+// forwarding rule kicks in: the callee may only be given availableGas
+// (after base has been deducted) minus a 64th of that amount, even if
+// callCost asks for more. Before that epoch the full requested callCost
+// is forwarded, capped only by the uint64 range.
+func callGas(gt params.GasTable, availableGas, base uint64, callCost *big.Int) (uint64, error) {
+	if gt.CreateBySuicide != 0 {
+		availableGas, ok := math.SafeSub(availableGas, base)
+		if !ok {
+			return 0, ErrGasUintOverflow
 		}
-
-		if r.stackPush > 0 && stack.len()-r.stackPop+r.stackPush > int(params.StackLimit.Int64()) {
-			return fmt.Errorf("stack limit reached %d (%d)", stack.len(), params.StackLimit.Int64())
+		gas := availableGas - availableGas/64
+		// If the bit length exceeds 64 bit we know that the newly calculated "gas"
+		// for EIP150 is smaller than the requested amount. Return the capped gas
+		// instead of erroring.
+		if callCost.BitLen() > 64 || gas < callCost.Uint64() {
+			return gas, nil
 		}
-
-		gas.Add(gas, r.gas)
+		return callCost.Uint64(), nil
 	}
-	return nil
+	if callCost.BitLen() > 64 {
+		return 0, ErrGasUintOverflow
+	}
+	return callCost.Uint64(), nil
 }
 
 // casts a arbitrary number to the amount of words (sets of 32 bytes)
-func toWordSize(size *big.Int) *big.Int {
-	tmp := new(big.Int)
-	tmp.Add(size, u256(31))
-	tmp.Div(tmp, u256(32))
-	return tmp
-}
-
-type req struct {
-	stackPop  int
-	gas       *big.Int
-	stackPush int
+func toWordSize(size uint64) uint64 {
+	if size > math.MaxUint64-31 {
+		return math.MaxUint64/32 + 1
+	}
+	return (size + 31) / 32
 }
 
-
-var _baseCheck = map[OpCode]req{
-	// opcode  |  stack pop | gas price | stack push
-	ADD:          {2, MinGasFastestStep, 1},
-	LT:           {2, MinGasFastestStep, 1},
-	GT:           {2, MinGasFastestStep, 1},
-	SLT:          {2, MinGasFastestStep, 1},
-	SGT:          {2, MinGasFastestStep, 1},
-	EQ:           {2, MinGasFastestStep, 1},
-	ISZERO:       {1, MinGasFastestStep, 1},
-	SUB:          {2, MinGasFastestStep, 1},
-	AND:          {2, MinGasFastestStep, 1},
-	OR:           {2, MinGasFastestStep, 1},
-	XOR:          {2, MinGasFastestStep, 1},
-	NOT:          {1, MinGasFastestStep, 1},
-	BYTE:         {2, MinGasFastestStep, 1},
-	CALLDATALOAD: {1, MinGasFastestStep, 1},
-	CALLDATACOPY: {3, MinGasFastestStep, 1},
-	MLOAD:        {1, MinGasFastestStep, 1},
-	MSTORE:       {2, MinGasFastestStep, 0},
-	MSTORE8:      {2, MinGasFastestStep, 0},
-	CODECOPY:     {3, MinGasFastestStep, 0},
-	MUL:          {2, MinGasFastStep, 1},
-	DIV:          {2, MinGasFastStep, 1},
-	SDIV:         {2, MinGasFastStep, 1},
-	MOD:          {2, MinGasFastStep, 1},
-	SMOD:         {2, MinGasFastStep, 1},
-	SIGNEXTEND:   {2, MinGasFastStep, 1},
-	ADDMOD:       {3, MinGasMidStep, 1},
-	MULMOD:       {3, MinGasMidStep, 1},
-	JUMP:         {1, MinGasMidStep, 0},
-	JUMPI:        {2, MinGasSlowStep, 0},
-	EXP:          {2, MinGasSlowStep, 1},
-	ADDRESS:      {0, MinGasQuickStep, 1},
-	ORIGIN:       {0, MinGasQuickStep, 1},
-	CALLER:       {0, MinGasQuickStep, 1},
-	CALLVALUE:    {0, MinGasQuickStep, 1},
-	CODESIZE:     {0, MinGasQuickStep, 1},
-	GASPRICE:     {0, MinGasQuickStep, 1},
-	COINBASE:     {0, MinGasQuickStep, 1},
-	TIMESTAMP:    {0, MinGasQuickStep, 1},
-	NUMBER:       {0, MinGasQuickStep, 1},
-	CALLDATASIZE: {0, MinGasQuickStep, 1},
-	DIFFICULTY:   {0, MinGasQuickStep, 1},
-	GASLIMIT:     {0, MinGasQuickStep, 1},
-	POP:          {1, MinGasQuickStep, 0},
-	PC:           {0, MinGasQuickStep, 1},
-	MSIZE:        {0, MinGasQuickStep, 1},
-	GAS:          {0, MinGasQuickStep, 1},
-	BLOCKHASH:    {1, MinGasExtStep, 1},
-	BALANCE:      {1, MinGasExtStep, 1},
-	EXTCODESIZE:  {1, MinGasExtStep, 1},
-	EXTCODECOPY:  {4, MinGasExtStep, 0},
-	SLOAD:        {1, params.SloadGas, 1},
-	SSTORE:       {2, Zero, 0},
-	SHA3:         {2, params.Sha3Gas, 1},
-	CREATE:       {3, params.CreateGas, 1},
-	CALL:         {7, params.CallGas, 1},
-	CALLCODE:     {7, params.CallGas, 1},
-	DELEGATECALL: {6, params.CallGas, 1},
-	JUMPDEST:     {0, params.JumpdestGas, 0},
-	SUICIDE:      {1, Zero, 0},
-	RETURN:       {2, Zero, 0},
-	PUSH1:        {0, GasFastestStep, 1},
-	DUP1:         {0, Zero, 1},
-}
\ No newline at end of file
@@ -0,0 +1,209 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// GasSchedule is the dynamic, per-epoch gas vector consulted by the
+// JumpTable (via GasSchedule.priceFor, from each opcode's gasCost) for
+// the opcodes miners are allowed to retarget. It is recomputed every
+// params.GasVoteWindow blocks by MedianGasSchedule and exposed to the
+// interpreter through Environment.GasSchedule(), replacing the naive
+// package-level map mutation this subsystem started out as.
+//
+// GasSchedule shares its field layout with params.GasVote, the
+// RLP-encoded form of a single block's vote stored on the header; the two
+// are convertible with a plain type conversion.
+type GasSchedule params.GasVote
+
+// FrontierGasSchedule is the gas schedule in effect before the first
+// retargeting epoch completes. Its step fields are the Gas*Step
+// constants; every other field is set to its MinGas* floor, which for
+// Sload, Sstore, Create, Call and Jumpdest differs from the fallback
+// price the JumpTable passes to stepGas for that opcode.
+var FrontierGasSchedule = GasSchedule{
+	QuickStep:   GasQuickStep,
+	FastestStep: GasFastestStep,
+	FastStep:    GasFastStep,
+	MidStep:     GasMidStep,
+	SlowStep:    GasSlowStep,
+	ExtStep:     GasExtStep,
+
+	Sload:       MinGasSload,
+	Sstore:      MinGasStore,
+	Sha3:        MinGasSha3,
+	Create:      MinGasCreate,
+	Call:        MinGasCall,
+	Jumpdest:    MinGasJumpdest,
+	Suicide:     MinGasSuicide,
+	Balance:     MinGasBalance,
+	Extcodesize: MinGasExtcodesize,
+	Extcodecopy: MinGasExtcodecopy,
+}
+
+// priceFor returns the dynamically voted price for op, or fallback if op
+// isn't one of the opcodes covered by the dynamic gas schedule.
+func (gs GasSchedule) priceFor(op OpCode, fallback uint64) uint64 {
+	switch op {
+	case ADD, LT, GT, SLT, SGT, EQ, ISZERO, SUB, AND, OR, XOR, NOT, BYTE,
+		CALLDATALOAD, CALLDATACOPY, MLOAD, MSTORE, MSTORE8, CODECOPY, PUSH1:
+		return gs.FastestStep
+	case MUL, DIV, SDIV, MOD, SMOD, SIGNEXTEND:
+		return gs.FastStep
+	case ADDMOD, MULMOD, JUMP:
+		return gs.MidStep
+	case JUMPI, EXP:
+		return gs.SlowStep
+	case ADDRESS, ORIGIN, CALLER, CALLVALUE, CODESIZE, GASPRICE, COINBASE,
+		TIMESTAMP, NUMBER, CALLDATASIZE, DIFFICULTY, GASLIMIT, POP, PC,
+		MSIZE, GAS:
+		return gs.QuickStep
+	case BLOCKHASH:
+		return gs.ExtStep
+	case BALANCE:
+		return gs.Balance
+	case EXTCODESIZE:
+		return gs.Extcodesize
+	case EXTCODECOPY:
+		return gs.Extcodecopy
+	case SLOAD:
+		return gs.Sload
+	case SSTORE:
+		return gs.Sstore
+	case SHA3:
+		return gs.Sha3
+	case CREATE:
+		return gs.Create
+	case CALL, CALLCODE, DELEGATECALL:
+		return gs.Call
+	case JUMPDEST:
+		return gs.Jumpdest
+	case SUICIDE:
+		return gs.Suicide
+	default:
+		return fallback
+	}
+}
+
+// gasScheduleFloors are the minimum values a GasSchedule field may take.
+// A vote proposing a lower value is rejected as a consensus violation:
+// letting a field reach (or approach) zero would let a contract spin an
+// opcode in a loop for next to nothing.
+var gasScheduleFloors = GasSchedule{
+	QuickStep:   MinGasQuickStep,
+	FastestStep: MinGasFastestStep,
+	FastStep:    MinGasFastStep,
+	MidStep:     MinGasMidStep,
+	SlowStep:    MinGasSlowStep,
+	ExtStep:     MinGasExtStep,
+
+	Sload:       MinGasSload,
+	Sstore:      MinGasStore,
+	Sha3:        MinGasSha3,
+	Create:      MinGasCreate,
+	Call:        MinGasCall,
+	Jumpdest:    MinGasJumpdest,
+	Suicide:     MinGasSuicide,
+	Balance:     MinGasBalance,
+	Extcodesize: MinGasExtcodesize,
+	Extcodecopy: MinGasExtcodecopy,
+}
+
+// CheckGasVote validates a single block's proposed gas vote against the
+// currently active schedule: every field must be at or above its
+// gasScheduleFloors floor, and may not move by more than 2x up or down
+// from the active value. Blocks proposing an out-of-range vote must be
+// rejected by consensus.
+func CheckGasVote(vote, active GasSchedule) error {
+	fields := []struct {
+		name           string
+		vote, floor, a uint64
+	}{
+		{"quickStep", vote.QuickStep, gasScheduleFloors.QuickStep, active.QuickStep},
+		{"fastestStep", vote.FastestStep, gasScheduleFloors.FastestStep, active.FastestStep},
+		{"fastStep", vote.FastStep, gasScheduleFloors.FastStep, active.FastStep},
+		{"midStep", vote.MidStep, gasScheduleFloors.MidStep, active.MidStep},
+		{"slowStep", vote.SlowStep, gasScheduleFloors.SlowStep, active.SlowStep},
+		{"extStep", vote.ExtStep, gasScheduleFloors.ExtStep, active.ExtStep},
+		{"sload", vote.Sload, gasScheduleFloors.Sload, active.Sload},
+		{"sstore", vote.Sstore, gasScheduleFloors.Sstore, active.Sstore},
+		{"sha3", vote.Sha3, gasScheduleFloors.Sha3, active.Sha3},
+		{"create", vote.Create, gasScheduleFloors.Create, active.Create},
+		{"call", vote.Call, gasScheduleFloors.Call, active.Call},
+		{"jumpdest", vote.Jumpdest, gasScheduleFloors.Jumpdest, active.Jumpdest},
+		{"suicide", vote.Suicide, gasScheduleFloors.Suicide, active.Suicide},
+		{"balance", vote.Balance, gasScheduleFloors.Balance, active.Balance},
+		{"extcodesize", vote.Extcodesize, gasScheduleFloors.Extcodesize, active.Extcodesize},
+		{"extcodecopy", vote.Extcodecopy, gasScheduleFloors.Extcodecopy, active.Extcodecopy},
+	}
+	for _, f := range fields {
+		if f.vote < f.floor {
+			return fmt.Errorf("gas vote sets %s to %d, below its floor of %d", f.name, f.vote, f.floor)
+		}
+		if f.vote > f.a*2 || f.vote < f.a/2 {
+			return fmt.Errorf("gas vote sets %s to %d, more than 2x away from the active value %d", f.name, f.vote, f.a)
+		}
+	}
+	return nil
+}
+
+// MedianGasSchedule computes the next active gas schedule as the
+// per-field median of votes, the set of votes cast over one
+// params.GasVoteWindow epoch. It does not validate the result against
+// the previous active schedule or the consensus floors — callers must
+// have already run CheckGasVote against every individual vote as it was
+// added to the window.
+//
+// votes must be non-empty; MedianGasSchedule panics otherwise, since an
+// epoch boundary is only ever reached after at least one vote has been
+// added to the window.
+func MedianGasSchedule(votes []GasSchedule) GasSchedule {
+	if len(votes) == 0 {
+		panic("MedianGasSchedule: no votes to compute a median from")
+	}
+	get := func(field func(GasSchedule) uint64) uint64 {
+		vals := make([]uint64, len(votes))
+		for i, v := range votes {
+			vals[i] = field(v)
+		}
+		sort.Slice(vals, func(i, j int) bool { return vals[i] < vals[j] })
+		return vals[len(vals)/2]
+	}
+	return GasSchedule{
+		QuickStep:   get(func(v GasSchedule) uint64 { return v.QuickStep }),
+		FastestStep: get(func(v GasSchedule) uint64 { return v.FastestStep }),
+		FastStep:    get(func(v GasSchedule) uint64 { return v.FastStep }),
+		MidStep:     get(func(v GasSchedule) uint64 { return v.MidStep }),
+		SlowStep:    get(func(v GasSchedule) uint64 { return v.SlowStep }),
+		ExtStep:     get(func(v GasSchedule) uint64 { return v.ExtStep }),
+		Sload:       get(func(v GasSchedule) uint64 { return v.Sload }),
+		Sstore:      get(func(v GasSchedule) uint64 { return v.Sstore }),
+		Sha3:        get(func(v GasSchedule) uint64 { return v.Sha3 }),
+		Create:      get(func(v GasSchedule) uint64 { return v.Create }),
+		Call:        get(func(v GasSchedule) uint64 { return v.Call }),
+		Jumpdest:    get(func(v GasSchedule) uint64 { return v.Jumpdest }),
+		Suicide:     get(func(v GasSchedule) uint64 { return v.Suicide }),
+		Balance:     get(func(v GasSchedule) uint64 { return v.Balance }),
+		Extcodesize: get(func(v GasSchedule) uint64 { return v.Extcodesize }),
+		Extcodecopy: get(func(v GasSchedule) uint64 { return v.Extcodecopy }),
+	}
+}
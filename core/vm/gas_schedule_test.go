@@ -0,0 +1,71 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import "testing"
+
+func TestCheckGasVoteFloor(t *testing.T) {
+	vote := FrontierGasSchedule
+	vote.QuickStep = gasScheduleFloors.QuickStep - 1
+
+	if err := CheckGasVote(vote, FrontierGasSchedule); err == nil {
+		t.Fatal("CheckGasVote accepted a vote below its floor")
+	}
+}
+
+func TestCheckGasVote2xClamp(t *testing.T) {
+	active := FrontierGasSchedule
+
+	tooHigh := active
+	tooHigh.Call = active.Call*2 + 1
+	if err := CheckGasVote(tooHigh, active); err == nil {
+		t.Fatal("CheckGasVote accepted a vote more than 2x above the active value")
+	}
+
+	tooLow := active
+	tooLow.Call = active.Call/2 - 1
+	if err := CheckGasVote(tooLow, active); err == nil {
+		t.Fatal("CheckGasVote accepted a vote more than 2x below the active value")
+	}
+
+	withinRange := active
+	withinRange.Call = active.Call * 2
+	if err := CheckGasVote(withinRange, active); err != nil {
+		t.Fatalf("CheckGasVote rejected a vote exactly at the 2x ceiling: %v", err)
+	}
+}
+
+func TestMedianGasSchedule(t *testing.T) {
+	votes := []GasSchedule{FrontierGasSchedule, FrontierGasSchedule, FrontierGasSchedule}
+	votes[0].Call = 10
+	votes[1].Call = 20
+	votes[2].Call = 30
+
+	median := MedianGasSchedule(votes)
+	if median.Call != 20 {
+		t.Fatalf("MedianGasSchedule().Call = %d, want 20", median.Call)
+	}
+}
+
+func TestMedianGasSchedulePanicsOnEmpty(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MedianGasSchedule(nil) did not panic")
+		}
+	}()
+	MedianGasSchedule(nil)
+}
@@ -0,0 +1,62 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestCallGasPreEIP150(t *testing.T) {
+	gt := params.GasTableFrontier
+
+	if got, err := callGas(gt, 100, 0, big.NewInt(40)); err != nil || got != 40 {
+		t.Fatalf("callGas() = %v, %v, want 40, nil", got, err)
+	}
+
+	overflow := new(big.Int).Lsh(big.NewInt(1), 64)
+	if _, err := callGas(gt, 100, 0, overflow); err != ErrGasUintOverflow {
+		t.Fatalf("callGas() error = %v, want ErrGasUintOverflow", err)
+	}
+}
+
+func TestCallGasEIP150(t *testing.T) {
+	gt := params.GasTableEIP150
+
+	// Requested cost is within the 63/64ths allowance and forwarded as-is.
+	if got, err := callGas(gt, 1000, 100, big.NewInt(10)); err != nil || got != 10 {
+		t.Fatalf("callGas() = %v, %v, want 10, nil", got, err)
+	}
+
+	// Requested cost exceeds the allowance, so it's capped at 63/64ths of
+	// what's left after base.
+	avail := uint64(1000 - 100)
+	want := avail - avail/64
+	if got, err := callGas(gt, 1000, 100, big.NewInt(1000)); err != nil || got != want {
+		t.Fatalf("callGas() = %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestCallGasEIP150BaseExceedsAvailable(t *testing.T) {
+	gt := params.GasTableEIP150
+
+	if _, err := callGas(gt, 50, 100, big.NewInt(10)); err != ErrGasUintOverflow {
+		t.Fatalf("callGas() error = %v, want ErrGasUintOverflow", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import "github.com/ethereum/go-ethereum/common"
+
+// opStop implements the STOP opcode. It halts execution without
+// returning any data.
+func opStop(pc *uint64, env Environment, contract *Contract, memory *Memory, stack *stack, pool *intPool) ([]byte, error) {
+	return nil, nil
+}
+
+// opReturn implements the RETURN opcode, copying the requested region of
+// memory out as the call's return data.
+func opReturn(pc *uint64, env Environment, contract *Contract, memory *Memory, stack *stack, pool *intPool) ([]byte, error) {
+	offset, size := stack.pop(), stack.pop()
+	ret := memory.GetPtr(offset.Int64(), size.Int64())
+	pool.put(offset, size)
+	return ret, nil
+}
+
+// opCall implements the CALL opcode. The gas forwarded to the callee is
+// capped by callGas, which enforces the EIP150 63/64 forwarding rule once
+// the active gas table's repricing epoch has started.
+func opCall(pc *uint64, env Environment, contract *Contract, memory *Memory, stack *stack, pool *intPool) ([]byte, error) {
+	gt := env.ChainConfig().GasTable(env.BlockNumber())
+
+	gasValue := stack.pop()
+	addr, value := stack.pop(), stack.pop()
+	inOffset, inSize := stack.pop(), stack.pop()
+	retOffset, retSize := stack.pop(), stack.pop()
+
+	gas, err := callGas(gt, contract.Gas.Uint64(), 0, gasValue)
+	if err != nil {
+		return nil, err
+	}
+	if !contract.UseGas(gas) {
+		return nil, ErrOutOfGas
+	}
+
+	args := memory.Get(inOffset.Int64(), inSize.Int64())
+	ret, returnGas, err := env.Call(contract, common.BigToAddress(addr), args, gas, contract.value, value)
+	if err != nil {
+		stack.push(pool.getZero())
+	} else {
+		stack.push(pool.get().SetUint64(1))
+		memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
+	}
+	contract.Gas.Add(contract.Gas, pool.get().SetUint64(returnGas))
+
+	return ret, nil
+}
+
+// opCallCode implements the CALLCODE opcode. It shares CALL's gas
+// forwarding rule via callGas but executes the callee's code in the
+// caller's own context.
+func opCallCode(pc *uint64, env Environment, contract *Contract, memory *Memory, stack *stack, pool *intPool) ([]byte, error) {
+	gt := env.ChainConfig().GasTable(env.BlockNumber())
+
+	gasValue := stack.pop()
+	addr, value := stack.pop(), stack.pop()
+	inOffset, inSize := stack.pop(), stack.pop()
+	retOffset, retSize := stack.pop(), stack.pop()
+
+	gas, err := callGas(gt, contract.Gas.Uint64(), 0, gasValue)
+	if err != nil {
+		return nil, err
+	}
+	if !contract.UseGas(gas) {
+		return nil, ErrOutOfGas
+	}
+
+	args := memory.Get(inOffset.Int64(), inSize.Int64())
+	ret, returnGas, err := env.CallCode(contract, common.BigToAddress(addr), args, gas, contract.value, value)
+	if err != nil {
+		stack.push(pool.getZero())
+	} else {
+		stack.push(pool.get().SetUint64(1))
+		memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
+	}
+	contract.Gas.Add(contract.Gas, pool.get().SetUint64(returnGas))
+
+	return ret, nil
+}
+
+// opDelegateCall implements the DELEGATECALL opcode introduced in
+// Homestead. Like CALLCODE it runs in the caller's context, but it also
+// forwards the caller's original value and sender, and is gas-metered
+// through the same callGas helper as the rest of the CALL family.
+func opDelegateCall(pc *uint64, env Environment, contract *Contract, memory *Memory, stack *stack, pool *intPool) ([]byte, error) {
+	gt := env.ChainConfig().GasTable(env.BlockNumber())
+
+	gasValue := stack.pop()
+	addr := stack.pop()
+	inOffset, inSize := stack.pop(), stack.pop()
+	retOffset, retSize := stack.pop(), stack.pop()
+
+	gas, err := callGas(gt, contract.Gas.Uint64(), 0, gasValue)
+	if err != nil {
+		return nil, err
+	}
+	if !contract.UseGas(gas) {
+		return nil, ErrOutOfGas
+	}
+
+	args := memory.Get(inOffset.Int64(), inSize.Int64())
+	ret, returnGas, err := env.DelegateCall(contract, common.BigToAddress(addr), args, gas)
+	if err != nil {
+		stack.push(pool.getZero())
+	} else {
+		stack.push(pool.get().SetUint64(1))
+		memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
+	}
+	contract.Gas.Add(contract.Gas, pool.get().SetUint64(returnGas))
+
+	return ret, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import "math/big"
+
+// poolLimit is the maximum number of *big.Int instances an intPool will
+// hold on to. Once full, put drops the excess rather than growing the
+// pool without bound for an unusually arithmetic-heavy contract.
+const poolLimit = 256
+
+// intPool is a pool of big.Int instances recycled across the opcodes of a
+// single contract execution, to cut down on the allocations the
+// interpreter would otherwise make on every arithmetic or memory-sizing
+// step.
+type intPool struct {
+	pool []*big.Int
+}
+
+// newIntPool creates an empty, ready to use int pool.
+func newIntPool() *intPool {
+	return &intPool{pool: make([]*big.Int, 0, poolLimit)}
+}
+
+// get retrieves a big int from the pool, allocating a new one if the pool
+// is empty. The returned value's contents are arbitrary and must be set
+// by the caller before use.
+func (p *intPool) get() *big.Int {
+	if len(p.pool) == 0 {
+		return new(big.Int)
+	}
+	i := p.pool[len(p.pool)-1]
+	p.pool = p.pool[:len(p.pool)-1]
+	return i
+}
+
+// getZero is like get but guarantees the returned value is zero.
+func (p *intPool) getZero() *big.Int {
+	return p.get().SetUint64(0)
+}
+
+// put returns ints to the pool so a later get/getZero call can reuse
+// them. The caller must not read or write through any of is again after
+// handing them to put.
+func (p *intPool) put(is ...*big.Int) {
+	if verifyIntegerPool {
+		verifyPoolPut(p, is...)
+	}
+	for _, i := range is {
+		if len(p.pool) >= poolLimit {
+			return
+		}
+		p.pool = append(p.pool, i)
+	}
+}
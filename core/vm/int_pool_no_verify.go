@@ -0,0 +1,28 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build !int_pool_verifier
+
+package vm
+
+import "math/big"
+
+// verifyIntegerPool is false in normal builds; see int_pool_verifier.go
+// for the debug variant enabled by the int_pool_verifier build tag.
+const verifyIntegerPool = false
+
+// verifyPoolPut is a no-op outside of -tags int_pool_verifier builds.
+func verifyPoolPut(p *intPool, is ...*big.Int) {}
@@ -0,0 +1,76 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIntPoolGetPut(t *testing.T) {
+	pool := newIntPool()
+
+	zero := pool.getZero()
+	if zero.Sign() != 0 {
+		t.Fatalf("getZero() = %v, want 0", zero)
+	}
+
+	a := pool.get()
+	a.SetUint64(42)
+	pool.put(a)
+
+	b := pool.get()
+	if b != a {
+		t.Fatalf("get() after put() returned a different *big.Int, pool isn't recycling")
+	}
+}
+
+func TestIntPoolPutBeyondLimit(t *testing.T) {
+	pool := newIntPool()
+
+	ints := make([]*big.Int, poolLimit+10)
+	for i := range ints {
+		ints[i] = new(big.Int)
+	}
+	pool.put(ints...)
+
+	if len(pool.pool) != poolLimit {
+		t.Fatalf("pool holds %d ints, want capped at poolLimit (%d)", len(pool.pool), poolLimit)
+	}
+}
+
+// BenchmarkIntPoolGetPut measures the recycling path opcodes use for their
+// scratch big.Ints, in contrast with BenchmarkNewBigInt's fresh
+// allocations, to quantify the allocation reduction an intPool buys the
+// interpreter's hot arithmetic path.
+func BenchmarkIntPoolGetPut(b *testing.B) {
+	pool := newIntPool()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		x := pool.get()
+		x.SetUint64(uint64(i))
+		pool.put(x)
+	}
+}
+
+func BenchmarkNewBigInt(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		x := new(big.Int)
+		x.SetUint64(uint64(i))
+	}
+}
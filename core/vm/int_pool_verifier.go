@@ -0,0 +1,45 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build int_pool_verifier
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// verifyIntegerPool is true when this file is compiled in via the
+// int_pool_verifier build tag. It is far too expensive to leave enabled
+// in production and exists only for -tags int_pool_verifier test runs.
+const verifyIntegerPool = true
+
+// verifyPoolPut panics if any of is is already present in p's pool,
+// which would mean the same *big.Int was put back twice without an
+// intervening get — the use-after-put bug class this build tag exists to
+// catch.
+func verifyPoolPut(p *intPool, is ...*big.Int) {
+	seen := make(map[*big.Int]bool, len(p.pool))
+	for _, i := range p.pool {
+		seen[i] = true
+	}
+	for _, i := range is {
+		if seen[i] {
+			panic(fmt.Sprintf("int pool: double-put of %p detected", i))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import "fmt"
+
+// ErrOutOfGas is returned when a contract runs out of gas mid-execution.
+var ErrOutOfGas = fmt.Errorf("out of gas")
+
+// Interpreter is used to run Ethereum based contracts and will utilise the
+// passed environment to query external sources for state information.
+// The Interpreter will run the byte code VM based on the passed
+// configuration.
+type Interpreter struct {
+	env Environment
+}
+
+// NewInterpreter returns a new instance of the Interpreter.
+func NewInterpreter(env Environment) *Interpreter {
+	return &Interpreter{env: env}
+}
+
+// jumpTable selects the JumpTable for the fork active at the current
+// block: Homestead added DELEGATECALL to what Frontier shipped with.
+func (in *Interpreter) jumpTable() JumpTable {
+	if in.env.ChainConfig().IsHomestead(in.env.BlockNumber()) {
+		return homesteadInstructionSet
+	}
+	return frontierInstructionSet
+}
+
+// Run loops and evaluates the contract's code with the given input data and
+// returns the return byte-slice and an error if one occurred. Each step
+// looks the current opcode up in the JumpTable once: that single
+// operation carries its stack validation, memory expansion sizing, gas
+// cost and execute function, where the interpreter used to consult
+// baseCheck and a separate execute switch in turn.
+func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, err error) {
+	contract.Input = input
+
+	var (
+		pc    = uint64(0)
+		op    OpCode
+		mem   = NewMemory()
+		stack = newstack()
+		gt    = in.env.ChainConfig().GasTable(in.env.BlockNumber())
+		gs    = in.env.GasSchedule()
+		jt    = in.jumpTable()
+	)
+
+	// Acquire a big.Int scratch pool for the lifetime of this contract
+	// execution. Opcodes draw their scratch ints from it via
+	// pool.get/getZero and return them via pool.put instead of
+	// allocating a fresh *big.Int on every step.
+	pool := newIntPool()
+
+	for {
+		op = contract.GetOp(pc)
+		operation := jt[op]
+		if !operation.valid {
+			return nil, fmt.Errorf("invalid opcode 0x%x", byte(op))
+		}
+		if err := operation.validateStack(stack); err != nil {
+			return nil, err
+		}
+
+		if operation.memorySize != nil {
+			size := operation.memorySize(stack)
+			if !size.IsUint64() {
+				return nil, ErrGasUintOverflow
+			}
+			// This only sizes memory; no gas is charged for the
+			// expansion. Apart from EXP's per-byte cost (gasExp), every
+			// JumpTable gasCost is a flat price that does not depend on
+			// the operands (SSTORE and SHA3 included), and memory grows
+			// without a memory-expansion charge.
+			mem.Resize(toWordSize(size.Uint64()) * 32)
+		}
+
+		cost, err := operation.gasCost(gt, gs, in.env, contract, stack, mem)
+		if err != nil {
+			return nil, err
+		}
+		if !contract.UseGas(cost) {
+			return nil, ErrOutOfGas
+		}
+
+		ret, err = operation.execute(&pc, in.env, contract, mem, stack, pool)
+		if err != nil {
+			return nil, err
+		}
+
+		if op == STOP || op == RETURN {
+			return ret, nil
+		}
+		pc++
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+type (
+	executionFunc       func(pc *uint64, env Environment, contract *Contract, memory *Memory, stack *stack, pool *intPool) ([]byte, error)
+	gasFunc             func(gt params.GasTable, gs GasSchedule, env Environment, contract *Contract, stack *stack, memory *Memory) (uint64, error)
+	stackValidationFunc func(stack *stack) error
+	memorySizeFunc      func(stack *stack) *big.Int
+)
+
+// operation bundles everything the interpreter needs to run a single
+// opcode: how to execute it, how much it costs, how to validate the
+// stack before running it, and how much memory it needs. This merges
+// what used to be two separate lookups — baseCheck's _baseCheck map for
+// stack and gas, and a switch statement elsewhere for execution — into
+// the single per-opcode JumpTable entry below.
+type operation struct {
+	execute       executionFunc
+	gasCost       gasFunc
+	validateStack stackValidationFunc
+	memorySize    memorySizeFunc
+
+	// valid is true for opcodes that have an entry in the jump table;
+	// the interpreter treats any other byte as an invalid instruction.
+	valid bool
+}
+
+// JumpTable maps the 256 possible opcode bytes to their operation.
+type JumpTable [256]operation
+
+var (
+	frontierInstructionSet  = NewFrontierInstructionSet()
+	homesteadInstructionSet = NewHomesteadInstructionSet()
+)
+
+// NewFrontierInstructionSet returns the JumpTable as it stood at the
+// Frontier release.
+func NewFrontierInstructionSet() JumpTable {
+	jt := JumpTable{
+		ADD:          {opUndefined, stepGas(ADD, GasFastestStep), stackFunc(2, 1), nil, true},
+		LT:           {opUndefined, stepGas(LT, GasFastestStep), stackFunc(2, 1), nil, true},
+		GT:           {opUndefined, stepGas(GT, GasFastestStep), stackFunc(2, 1), nil, true},
+		SLT:          {opUndefined, stepGas(SLT, GasFastestStep), stackFunc(2, 1), nil, true},
+		SGT:          {opUndefined, stepGas(SGT, GasFastestStep), stackFunc(2, 1), nil, true},
+		EQ:           {opUndefined, stepGas(EQ, GasFastestStep), stackFunc(2, 1), nil, true},
+		ISZERO:       {opUndefined, stepGas(ISZERO, GasFastestStep), stackFunc(1, 1), nil, true},
+		SUB:          {opUndefined, stepGas(SUB, GasFastestStep), stackFunc(2, 1), nil, true},
+		AND:          {opUndefined, stepGas(AND, GasFastestStep), stackFunc(2, 1), nil, true},
+		OR:           {opUndefined, stepGas(OR, GasFastestStep), stackFunc(2, 1), nil, true},
+		XOR:          {opUndefined, stepGas(XOR, GasFastestStep), stackFunc(2, 1), nil, true},
+		NOT:          {opUndefined, stepGas(NOT, GasFastestStep), stackFunc(1, 1), nil, true},
+		BYTE:         {opUndefined, stepGas(BYTE, GasFastestStep), stackFunc(2, 1), nil, true},
+		CALLDATALOAD: {opUndefined, stepGas(CALLDATALOAD, GasFastestStep), stackFunc(1, 1), nil, true},
+		CALLDATACOPY: {opUndefined, stepGas(CALLDATACOPY, GasFastestStep), stackFunc(3, 0), memoryCallDataCopy, true},
+		MLOAD:        {opUndefined, stepGas(MLOAD, GasFastestStep), stackFunc(1, 1), memoryMLoad, true},
+		MSTORE:       {opUndefined, stepGas(MSTORE, GasFastestStep), stackFunc(2, 0), memoryMStore, true},
+		MSTORE8:      {opUndefined, stepGas(MSTORE8, GasFastestStep), stackFunc(2, 0), memoryMStore8, true},
+		CODECOPY:     {opUndefined, stepGas(CODECOPY, GasFastestStep), stackFunc(3, 0), memoryCodeCopy, true},
+		MUL:          {opUndefined, stepGas(MUL, GasFastStep), stackFunc(2, 1), nil, true},
+		DIV:          {opUndefined, stepGas(DIV, GasFastStep), stackFunc(2, 1), nil, true},
+		SDIV:         {opUndefined, stepGas(SDIV, GasFastStep), stackFunc(2, 1), nil, true},
+		MOD:          {opUndefined, stepGas(MOD, GasFastStep), stackFunc(2, 1), nil, true},
+		SMOD:         {opUndefined, stepGas(SMOD, GasFastStep), stackFunc(2, 1), nil, true},
+		SIGNEXTEND:   {opUndefined, stepGas(SIGNEXTEND, GasFastStep), stackFunc(2, 1), nil, true},
+		ADDMOD:       {opUndefined, stepGas(ADDMOD, GasMidStep), stackFunc(3, 1), nil, true},
+		MULMOD:       {opUndefined, stepGas(MULMOD, GasMidStep), stackFunc(3, 1), nil, true},
+		JUMP:         {opUndefined, stepGas(JUMP, GasMidStep), stackFunc(1, 0), nil, true},
+		JUMPI:        {opUndefined, stepGas(JUMPI, GasSlowStep), stackFunc(2, 0), nil, true},
+		EXP:          {opUndefined, gasExp, stackFunc(2, 1), nil, true},
+		ADDRESS:      {opUndefined, stepGas(ADDRESS, GasQuickStep), stackFunc(0, 1), nil, true},
+		ORIGIN:       {opUndefined, stepGas(ORIGIN, GasQuickStep), stackFunc(0, 1), nil, true},
+		CALLER:       {opUndefined, stepGas(CALLER, GasQuickStep), stackFunc(0, 1), nil, true},
+		CALLVALUE:    {opUndefined, stepGas(CALLVALUE, GasQuickStep), stackFunc(0, 1), nil, true},
+		CODESIZE:     {opUndefined, stepGas(CODESIZE, GasQuickStep), stackFunc(0, 1), nil, true},
+		GASPRICE:     {opUndefined, stepGas(GASPRICE, GasQuickStep), stackFunc(0, 1), nil, true},
+		COINBASE:     {opUndefined, stepGas(COINBASE, GasQuickStep), stackFunc(0, 1), nil, true},
+		TIMESTAMP:    {opUndefined, stepGas(TIMESTAMP, GasQuickStep), stackFunc(0, 1), nil, true},
+		NUMBER:       {opUndefined, stepGas(NUMBER, GasQuickStep), stackFunc(0, 1), nil, true},
+		CALLDATASIZE: {opUndefined, stepGas(CALLDATASIZE, GasQuickStep), stackFunc(0, 1), nil, true},
+		DIFFICULTY:   {opUndefined, stepGas(DIFFICULTY, GasQuickStep), stackFunc(0, 1), nil, true},
+		GASLIMIT:     {opUndefined, stepGas(GASLIMIT, GasQuickStep), stackFunc(0, 1), nil, true},
+		POP:          {opUndefined, stepGas(POP, GasQuickStep), stackFunc(1, 0), nil, true},
+		PC:           {opUndefined, stepGas(PC, GasQuickStep), stackFunc(0, 1), nil, true},
+		MSIZE:        {opUndefined, stepGas(MSIZE, GasQuickStep), stackFunc(0, 1), nil, true},
+		GAS:          {opUndefined, stepGas(GAS, GasQuickStep), stackFunc(0, 1), nil, true},
+		BLOCKHASH:    {opUndefined, stepGas(BLOCKHASH, GasExtStep), stackFunc(1, 1), nil, true},
+		BALANCE:      {opUndefined, stepGas(BALANCE, GasExtStep), stackFunc(1, 1), nil, true},
+		EXTCODESIZE:  {opUndefined, stepGas(EXTCODESIZE, GasExtStep), stackFunc(1, 1), nil, true},
+		EXTCODECOPY:  {opUndefined, stepGas(EXTCODECOPY, GasExtStep), stackFunc(4, 0), memoryExtCodeCopy, true},
+		SLOAD:        {opUndefined, stepGas(SLOAD, params.SloadGas), stackFunc(1, 1), nil, true},
+		SSTORE:       {opUndefined, stepGas(SSTORE, GasStop), stackFunc(2, 0), nil, true},
+		SHA3:         {opUndefined, stepGas(SHA3, params.Sha3Gas), stackFunc(2, 1), memorySha3, true},
+		CREATE:       {opUndefined, stepGas(CREATE, params.CreateGas), stackFunc(3, 1), memoryCreate, true},
+		CALL:         {opCall, stepGas(CALL, params.CallGas), stackFunc(7, 1), memoryCall, true},
+		CALLCODE:     {opCallCode, stepGas(CALLCODE, params.CallGas), stackFunc(7, 1), memoryCall, true},
+		JUMPDEST:     {opUndefined, stepGas(JUMPDEST, params.JumpdestGas), stackFunc(0, 0), nil, true},
+		SUICIDE:      {opUndefined, stepGas(SUICIDE, GasStop), stackFunc(1, 0), nil, true},
+		RETURN:       {opReturn, stepGas(RETURN, GasStop), stackFunc(2, 0), memoryReturn, true},
+		STOP:         {opStop, constGasFunc(GasStop), stackFunc(0, 0), nil, true},
+	}
+
+	for op := PUSH1; op <= PUSH32; op++ {
+		jt[op] = operation{opUndefined, stepGas(PUSH1, GasFastestStep), stackFunc(0, 1), nil, true}
+	}
+	for op := DUP1; op <= DUP16; op++ {
+		jt[op] = operation{opUndefined, constGasFunc(GasStop), stackFunc(0, 1), nil, true}
+	}
+	return jt
+}
+
+// NewHomesteadInstructionSet returns the JumpTable as of the Homestead
+// release: everything Frontier had, plus DELEGATECALL (EIP7).
+func NewHomesteadInstructionSet() JumpTable {
+	jt := NewFrontierInstructionSet()
+	jt[DELEGATECALL] = operation{opDelegateCall, stepGas(DELEGATECALL, params.CallGas), stackFunc(6, 1), memoryDelegateCall, true}
+	return jt
+}
+
+// stackFunc returns a validateStack that requires pop stack items and,
+// when push would grow the stack, ensures the post-execution depth stays
+// within params.StackLimit. It replaces the stackPop/stackPush pair the
+// old _baseCheck req table carried per opcode.
+func stackFunc(pop, push int) stackValidationFunc {
+	return func(stack *stack) error {
+		if err := stack.require(pop); err != nil {
+			return err
+		}
+		if push > 0 && stack.len()-pop+push > int(params.StackLimit) {
+			return fmt.Errorf("stack limit reached %d (%d)", stack.len(), params.StackLimit)
+		}
+		return nil
+	}
+}
+
+// constGasFunc returns a gasFunc charging a fixed price, ignoring the
+// active gas table and dynamic gas schedule entirely.
+func constGasFunc(gas uint64) gasFunc {
+	return func(gt params.GasTable, gs GasSchedule, env Environment, contract *Contract, stack *stack, memory *Memory) (uint64, error) {
+		return gas, nil
+	}
+}
+
+// stepGas returns the gasFunc for op: the dynamic gas schedule's vote
+// for op if it has one, falling back to fallback. For the opcodes the
+// active gas table reprices (see opGasPrice) the gas table's price takes
+// precedence over both, under every gas table, not only the EIP150 one.
+func stepGas(op OpCode, fallback uint64) gasFunc {
+	return func(gt params.GasTable, gs GasSchedule, env Environment, contract *Contract, stack *stack, memory *Memory) (uint64, error) {
+		return opGasPrice(op, gs.priceFor(op, fallback), gt), nil
+	}
+}
+
+// opUndefined is the execute function for opcodes this interpreter
+// doesn't implement yet. Their JumpTable slots still carry correct gas
+// and stack metadata so callGas, the dynamic gas schedule, and stack
+// depth accounting all behave correctly even before an execute is
+// written for them.
+func opUndefined(pc *uint64, env Environment, contract *Contract, memory *Memory, stack *stack, pool *intPool) ([]byte, error) {
+	return nil, fmt.Errorf("opcode not implemented")
+}
+
+var (
+	big1  = big.NewInt(1)
+	big32 = big.NewInt(32)
+)
+
+// calcMemSize returns the highest memory offset (off+l) an opcode taking
+// a memory offset/length pair from the stack will touch. A zero length
+// never expands memory, regardless of offset.
+func calcMemSize(off, l *big.Int) *big.Int {
+	if l.Sign() == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Add(off, l)
+}
+
+func memoryMLoad(stack *stack) *big.Int        { return calcMemSize(stack.back(0), big32) }
+func memoryMStore8(stack *stack) *big.Int      { return calcMemSize(stack.back(0), big1) }
+func memoryMStore(stack *stack) *big.Int       { return calcMemSize(stack.back(0), big32) }
+func memorySha3(stack *stack) *big.Int         { return calcMemSize(stack.back(0), stack.back(1)) }
+func memoryCallDataCopy(stack *stack) *big.Int { return calcMemSize(stack.back(0), stack.back(2)) }
+func memoryCodeCopy(stack *stack) *big.Int     { return calcMemSize(stack.back(0), stack.back(2)) }
+func memoryExtCodeCopy(stack *stack) *big.Int  { return calcMemSize(stack.back(1), stack.back(3)) }
+func memoryReturn(stack *stack) *big.Int       { return calcMemSize(stack.back(0), stack.back(1)) }
+func memoryCreate(stack *stack) *big.Int       { return calcMemSize(stack.back(1), stack.back(2)) }
+
+func memoryCall(stack *stack) *big.Int {
+	in := calcMemSize(stack.back(3), stack.back(4))
+	out := calcMemSize(stack.back(5), stack.back(6))
+	if in.Cmp(out) > 0 {
+		return in
+	}
+	return out
+}
+
+func memoryDelegateCall(stack *stack) *big.Int {
+	in := calcMemSize(stack.back(2), stack.back(3))
+	out := calcMemSize(stack.back(4), stack.back(5))
+	if in.Cmp(out) > 0 {
+		return in
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+// GasTable organizes gas prices for different ethereum phases.
+type GasTable struct {
+	ExtcodeSize uint64
+	ExtcodeCopy uint64
+	Balance     uint64
+	SLoad       uint64
+	Calls       uint64
+	Suicide     uint64
+
+	// ExpByte is the per-byte surcharge added to EXP's step price for
+	// every byte of its exponent operand; see core/vm.gasExp.
+	ExpByte uint64
+
+	// CreateBySuicide occurs when the
+	// refunded account is one that does
+	// not exist. This logic is similar to the
+	// CREATE opcode, except it's run in the
+	// opposite direction.
+	//
+	// It is a non-zero value only for the gas
+	// tables that come after the EIP150 repricing,
+	// and is used as a sentinel to detect whether
+	// the currently active table is pre- or
+	// post-repricing.
+	CreateBySuicide uint64
+}
+
+// Variables containing gas prices for different ethereum phases.
+var (
+	// GasTableFrontier contain the gas prices for
+	// the frontier phase.
+	GasTableFrontier = GasTable{
+		ExtcodeSize: 20,
+		ExtcodeCopy: 20,
+		Balance:     20,
+		SLoad:       50,
+		Calls:       40,
+		Suicide:     0,
+		ExpByte:     10,
+	}
+
+	// GasTableHomestead contain the gas prices for
+	// the homestead phase.
+	GasTableHomestead = GasTable{
+		ExtcodeSize: 20,
+		ExtcodeCopy: 20,
+		Balance:     20,
+		SLoad:       50,
+		Calls:       40,
+		Suicide:     0,
+		ExpByte:     10,
+	}
+
+	// GasTableEIP150 contain the gas re-prices for
+	// the EIP150 phase.
+	GasTableEIP150 = GasTable{
+		ExtcodeSize: 700,
+		ExtcodeCopy: 700,
+		Balance:     400,
+		SLoad:       200,
+		Calls:       700,
+		Suicide:     5000,
+		ExpByte:     10,
+
+		// CreateBySuicide occurs when the
+		// refunded account is one that does
+		// not exist. This logic is similar to the
+		// CREATE opcode, except it's run in the
+		// opposite direction.
+		CreateBySuicide: 25000,
+	}
+)
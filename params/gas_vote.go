@@ -0,0 +1,47 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+// GasVoteWindow is the number of blocks that make up one gas retargeting
+// epoch. Every GasVoteWindow blocks the chain recomputes the active gas
+// schedule as the per-field median of the votes cast over the preceding
+// window.
+const GasVoteWindow = 64
+
+// GasVote is a single block's proposed dynamic gas schedule. It is
+// carried on the block header (Header.GasVote) so the active schedule
+// for any point in the chain's history can be recomputed from the
+// headers alone, without any side-stored state.
+type GasVote struct {
+	QuickStep   uint64
+	FastestStep uint64
+	FastStep    uint64
+	MidStep     uint64
+	SlowStep    uint64
+	ExtStep     uint64
+
+	Sload       uint64
+	Sstore      uint64
+	Sha3        uint64
+	Create      uint64
+	Call        uint64
+	Jumpdest    uint64
+	Suicide     uint64
+	Balance     uint64
+	Extcodesize uint64
+	Extcodecopy uint64
+}
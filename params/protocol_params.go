@@ -0,0 +1,30 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+// Fixed gas costs passed as fallback prices in core/vm's JumpTable. The
+// SLOAD and CALL prices actually charged come from the active GasTable
+// (SLoad and Calls), not from SloadGas and CallGas.
+const (
+	SloadGas    uint64 = 50
+	Sha3Gas     uint64 = 30
+	CreateGas   uint64 = 32000
+	CallGas     uint64 = 40
+	JumpdestGas uint64 = 1
+
+	StackLimit uint64 = 1024
+)